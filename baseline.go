@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+type (
+	// baseline is the JSON-serializable snapshot persisted at --baseline
+	// so that runs can be diffed without re-parsing the Vim legend output.
+	baseline struct {
+		Files map[string]baselineFile `json:"files"`
+	}
+
+	baselineFile struct {
+		Hits      int   `json:"hits"`
+		Misses    int   `json:"misses"`
+		MissLines []int `json:"miss_lines"`
+	}
+)
+
+func snapshotBaseline(ctx context) baseline {
+	b := baseline{Files: make(map[string]baselineFile, len(ctx.Results))}
+	for f, r := range ctx.Results {
+		b.Files[f] = baselineFile{
+			Hits:      r.HitCount(),
+			Misses:    r.MissCount(),
+			MissLines: append([]int(nil), r.Misses...),
+		}
+	}
+	return b
+}
+
+func loadBaseline(path string) (baseline, error) {
+	var b baseline
+
+	f, err := os.Open(path)
+	if err != nil {
+		return b, err
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&b)
+	return b, err
+}
+
+func writeBaseline(path string, b baseline) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(b)
+}
+
+func percentage(hits, misses int) float64 {
+	if hits+misses == 0 {
+		return 100
+	}
+	return float64(hits) / float64(hits+misses) * 100
+}
+
+// newMisses returns the lines in misses that weren't already misses in the
+// baseline - i.e. lines that regressed from hit (or nonexistent) to missed.
+func newMisses(misses, baselineMisses []int) []int {
+	wasMissed := make(map[int]bool, len(baselineMisses))
+	for _, ln := range baselineMisses {
+		wasMissed[ln] = true
+	}
+
+	var fresh []int
+	for _, ln := range misses {
+		if !wasMissed[ln] {
+			fresh = append(fresh, ln)
+		}
+	}
+	return fresh
+}
+
+// gateCoverage diffs ctx against the baseline recorded at opts.baseline (if
+// any), prints a report of the regressions, persists ctx as the new
+// baseline for the next run, and reports whether the gate passed.
+func gateCoverage(ctx context, opts options) (passed bool) {
+	old, err := loadBaseline(opts.baseline)
+	haveOld := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		log.Print(err)
+	}
+
+	passed = true
+
+	tabs := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(tabs, "File\tOld %%\tNew %%\tDelta\tNew Misses\n")
+
+	var totalHits, totalMisses int
+	for _, f := range sortedFilenames(ctx) {
+		r := ctx.Results[f]
+		totalHits += r.HitCount()
+		totalMisses += r.MissCount()
+
+		oldPct, fresh := percentage(r.HitCount(), r.MissCount()), []int(nil)
+		if haveOld {
+			if of, ok := old.Files[f]; ok {
+				oldPct = percentage(of.Hits, of.Misses)
+				fresh = newMisses(r.Misses, of.MissLines)
+			} else {
+				oldPct = 0
+				fresh = r.Misses
+			}
+		}
+
+		newPct := percentage(r.HitCount(), r.MissCount())
+		delta := newPct - oldPct
+		if opts.failOnRegression && delta < 0 {
+			passed = false
+		}
+
+		fmt.Fprintf(tabs, "%s\t%.2f\t%.2f\t%+.2f\t%v\n", f, oldPct, newPct, delta, fresh)
+	}
+
+	if haveOld {
+		for f, of := range old.Files {
+			if _, ok := ctx.Results[f]; !ok {
+				fmt.Fprintf(tabs, "%s\t%.2f\t-\tremoved\t\n", f, percentage(of.Hits, of.Misses))
+			}
+		}
+	}
+	tabs.Flush()
+
+	overall := percentage(totalHits, totalMisses)
+	fmt.Printf("Overall coverage: %.2f%%\n", overall)
+	if opts.failUnder > 0 && overall < opts.failUnder {
+		passed = false
+	}
+
+	if err := writeBaseline(opts.baseline, snapshotBaseline(ctx)); err != nil {
+		log.Print(err)
+	}
+
+	return passed
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func resultWith(filename string, hits, misses []int) *result {
+	counts := make(map[int]int)
+	for _, ln := range hits {
+		counts[ln] = 1
+	}
+	return &result{filename: filename, Counts: counts, Hits: hits, Misses: misses}
+}
+
+func TestGateCoverageFailUnder(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+	ctx := context{Results: map[string]*result{
+		"pkg/foo.go": resultWith("pkg/foo.go", []int{1}, []int{2}),
+	}}
+
+	if passed := gateCoverage(ctx, options{baseline: baselinePath, failUnder: 90}); passed {
+		t.Errorf("gateCoverage() = true, want false (50%% coverage is under 90%%)")
+	}
+	if passed := gateCoverage(ctx, options{baseline: baselinePath, failUnder: 10}); !passed {
+		t.Errorf("gateCoverage() = false, want true (50%% coverage is over 10%%)")
+	}
+}
+
+func TestGateCoverageFailOnRegression(t *testing.T) {
+	baselinePath := filepath.Join(t.TempDir(), "baseline.json")
+
+	good := context{Results: map[string]*result{
+		"pkg/foo.go": resultWith("pkg/foo.go", []int{1, 2}, nil),
+	}}
+	if passed := gateCoverage(good, options{baseline: baselinePath, failOnRegression: true}); !passed {
+		t.Errorf("gateCoverage() = false, want true on the first (baseline-establishing) run")
+	}
+
+	worse := context{Results: map[string]*result{
+		"pkg/foo.go": resultWith("pkg/foo.go", []int{1}, []int{2}),
+	}}
+	if passed := gateCoverage(worse, options{baseline: baselinePath, failOnRegression: true}); passed {
+		t.Errorf("gateCoverage() = true, want false (coverage dropped from 100%% to 50%%)")
+	}
+
+	same := context{Results: map[string]*result{
+		"pkg/foo.go": resultWith("pkg/foo.go", []int{1}, []int{2}),
+	}}
+	if passed := gateCoverage(same, options{baseline: baselinePath, failOnRegression: true}); !passed {
+		t.Errorf("gateCoverage() = false, want true (coverage unchanged from prior run)")
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// buildFileCoverage used to classify 1-based block line numbers against a
+// 0..r.lines-1 loop, which silently dropped the last source line. A
+// 2-line file whose only code is on line 2 reproduces it directly.
+func TestBuildFileCoverageLastLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tiny.go")
+	if err := ioutil.WriteFile(path, []byte("package foo\nfunc F() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx context
+	ctx.Results = map[string]*result{}
+	r := &result{filename: "tiny.go", Counts: map[int]int{2: 3}}
+	ctx.Results["tiny.go"] = r
+
+	buildFileCoverage(&ctx, dir, "tiny.go", r)
+
+	if got := r.HitCount(); got != 1 {
+		t.Errorf("HitCount() = %d, want 1", got)
+	}
+	if got := r.MissCount(); got != 0 {
+		t.Errorf("MissCount() = %d, want 0", got)
+	}
+	if !reflect.DeepEqual(r.Hits, []int{2}) {
+		t.Errorf("Hits = %v, want [2]", r.Hits)
+	}
+
+	for _, ln := range append(append(append([]int{}, r.Hits...), r.Misses...), r.Ignored...) {
+		if ln == 0 {
+			t.Errorf("result contains phantom line 0: hits=%v misses=%v ignored=%v", r.Hits, r.Misses, r.Ignored)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/docopt/docopt-go"
+)
+
+func asStrings(v interface{}) []string {
+	switch vv := v.(type) {
+	case []string:
+		return vv
+	case []interface{}:
+		out := make([]string, len(vv))
+		for i, x := range vv {
+			out[i] = fmt.Sprint(x)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// Repeating the "[--include=<glob>]... [--exclude=<glob>]..." group on
+// every Usage alternative confused docopt-go into duplicating later
+// repeated values; it only needs to appear on the <outpath> alternative,
+// since --noout never takes --include/--exclude.
+func TestParseRepeatedExcludeAndInclude(t *testing.T) {
+	argv := []string{
+		"--exclude=a", "--exclude=b", "--exclude=c",
+		"--include=x", "--include=y",
+		"out.legend", "file.cov",
+	}
+	parsed, err := docopt.Parse(docstring, argv, true, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := asStrings(parsed["--exclude"]), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("--exclude = %v, want %v", got, want)
+	}
+	if got, want := asStrings(parsed["--include"]), []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("--include = %v, want %v", got, want)
+	}
+}
+
+// --noout has to be its own Usage alternative - folding it into the
+// <outpath> alternative as "(<outpath> | --noout)" parses, but docopt-go
+// never matches it successfully at runtime.
+func TestParseNoout(t *testing.T) {
+	parsed, err := docopt.Parse(docstring, []string{"--noout", "file.cov"}, true, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if noout, _ := parsed["--noout"].(bool); !noout {
+		t.Errorf("--noout = %v, want true", parsed["--noout"])
+	}
+	if got, want := asStrings(parsed["<sourcefiles>"]), []string{"file.cov"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("<sourcefiles> = %v, want %v", got, want)
+	}
+}
+
+func TestParseHitlistNoout(t *testing.T) {
+	parsed, err := docopt.Parse(docstring, []string{"--hitlist", "--noout", "file.cov"}, true, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hitlist, _ := parsed["--hitlist"].(bool); !hitlist {
+		t.Errorf("--hitlist = %v, want true", parsed["--hitlist"])
+	}
+	if noout, _ := parsed["--noout"].(bool); !noout {
+		t.Errorf("--noout = %v, want true", parsed["--noout"])
+	}
+}
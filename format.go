@@ -0,0 +1,95 @@
+package main
+
+import "sort"
+
+type (
+	lineCoverage struct {
+		Line, Count int
+	}
+
+	lcovContext struct {
+		Files []*lcovFile
+	}
+
+	lcovFile struct {
+		Name       string
+		Lines      []lineCoverage
+		LinesFound int
+		LinesHit   int
+	}
+
+	coberturaContext struct {
+		LineRate float64
+		Files    []*coberturaFile
+	}
+
+	coberturaFile struct {
+		Name     string
+		LineRate float64
+		Lines    []lineCoverage
+	}
+)
+
+// LineCoverage returns the per-line hit counts for rz, sorted by line
+// number, combining Hits (with their recorded count) and Misses (count 0).
+// Ignored lines carry no coverage information and are omitted.
+func (rz *result) LineCoverage() []lineCoverage {
+	lc := make([]lineCoverage, 0, len(rz.Hits)+len(rz.Misses))
+	for _, ln := range rz.Hits {
+		lc = append(lc, lineCoverage{Line: ln, Count: rz.Counts[ln]})
+	}
+	for _, ln := range rz.Misses {
+		lc = append(lc, lineCoverage{Line: ln, Count: 0})
+	}
+	sort.Slice(lc, func(i, j int) bool { return lc[i].Line < lc[j].Line })
+	return lc
+}
+
+func lineRate(hits, misses int) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+func buildLcovContext(ctx context) lcovContext {
+	var lctx lcovContext
+	for _, f := range sortedFilenames(ctx) {
+		r := ctx.Results[f]
+		lctx.Files = append(lctx.Files, &lcovFile{
+			Name:       f,
+			Lines:      r.LineCoverage(),
+			LinesFound: r.HitCount() + r.MissCount(),
+			LinesHit:   r.HitCount(),
+		})
+	}
+	return lctx
+}
+
+func buildCoberturaContext(ctx context) coberturaContext {
+	cctx := coberturaContext{}
+
+	var hits, misses int
+	for _, f := range sortedFilenames(ctx) {
+		r := ctx.Results[f]
+		hits += r.HitCount()
+		misses += r.MissCount()
+		cctx.Files = append(cctx.Files, &coberturaFile{
+			Name:     f,
+			LineRate: lineRate(r.HitCount(), r.MissCount()),
+			Lines:    r.LineCoverage(),
+		})
+	}
+	cctx.LineRate = lineRate(hits, misses)
+
+	return cctx
+}
+
+func sortedFilenames(ctx context) []string {
+	names := make([]string, 0, len(ctx.Results))
+	for f := range ctx.Results {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+	return names
+}
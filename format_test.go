@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/cover"
+)
+
+// mergeProfiles sums Count only for blocks that share the same
+// (StartLine, StartCol, EndLine, EndCol) across two profiles for the same
+// file - distinct blocks are kept separate rather than merged.
+func TestMergeProfilesSumsIdenticalBlocks(t *testing.T) {
+	a := &cover.Profile{FileName: "pkg/foo.go", Blocks: []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 3, EndCol: 2, Count: 1},
+	}}
+	b := &cover.Profile{FileName: "pkg/foo.go", Blocks: []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 3, EndCol: 2, Count: 2},
+		{StartLine: 4, StartCol: 1, EndLine: 4, EndCol: 5, Count: 1},
+	}}
+
+	merged := mergeProfiles([]*cover.Profile{a, b})
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+
+	want := []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 3, EndCol: 2, Count: 3},
+		{StartLine: 4, StartCol: 1, EndLine: 4, EndCol: 5, Count: 1},
+	}
+	if !reflect.DeepEqual(merged[0].Blocks, want) {
+		t.Errorf("Blocks = %v, want %v", merged[0].Blocks, want)
+	}
+}
+
+func fixtureContext() context {
+	return context{
+		Results: map[string]*result{
+			"pkg/foo.go": {
+				filename: "pkg/foo.go",
+				Counts:   map[int]int{1: 2, 2: 0},
+				Hits:     []int{1},
+				Misses:   []int{2},
+			},
+		},
+	}
+}
+
+func TestBuildLcovContext(t *testing.T) {
+	lctx := buildLcovContext(fixtureContext())
+
+	if len(lctx.Files) != 1 {
+		t.Fatalf("len(Files) = %d, want 1", len(lctx.Files))
+	}
+	f := lctx.Files[0]
+	if f.Name != "pkg/foo.go" {
+		t.Errorf("Name = %q, want %q", f.Name, "pkg/foo.go")
+	}
+	if f.LinesFound != 2 || f.LinesHit != 1 {
+		t.Errorf("LinesFound/LinesHit = %d/%d, want 2/1", f.LinesFound, f.LinesHit)
+	}
+	want := []lineCoverage{{Line: 1, Count: 2}, {Line: 2, Count: 0}}
+	if !reflect.DeepEqual(f.Lines, want) {
+		t.Errorf("Lines = %v, want %v", f.Lines, want)
+	}
+}
+
+func TestBuildCoberturaContext(t *testing.T) {
+	cctx := buildCoberturaContext(fixtureContext())
+
+	if len(cctx.Files) != 1 {
+		t.Fatalf("len(Files) = %d, want 1", len(cctx.Files))
+	}
+	if got, want := cctx.LineRate, 0.5; got != want {
+		t.Errorf("LineRate = %v, want %v", got, want)
+	}
+	if got, want := cctx.Files[0].LineRate, 0.5; got != want {
+		t.Errorf("Files[0].LineRate = %v, want %v", got, want)
+	}
+}
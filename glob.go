@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// expandSourceFiles turns a sourcefiles entry into the concrete profile
+// paths it refers to. Plain paths pass through unchanged (so cover.ParseProfiles
+// still reports a clear error for a missing file); patterns containing "**"
+// are walked recursively, and anything else goes through filepath.Glob.
+func expandSourceFiles(pattern string) []string {
+	switch {
+	case strings.Contains(pattern, "**"):
+		return expandDoublestarGlob(pattern)
+
+	case strings.ContainsAny(pattern, "*?["):
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+		return matches
+
+	default:
+		return []string{pattern}
+	}
+}
+
+// expandDoublestarGlob walks the directory tree rooted at the portion of
+// pattern before its first "**", matching each file's full path against
+// the complete pattern via matchPattern - the same "**" regexp translation
+// --include/--exclude already use - so files nested arbitrarily deep under
+// the "**" are found, not just ones sitting directly in the walked directory.
+func expandDoublestarGlob(pattern string) []string {
+	base := doublestarBase(pattern)
+
+	var matches []string
+	filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if matchPattern(pattern, filepath.ToSlash(path)) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches
+}
+
+func doublestarBase(pattern string) string {
+	if i := strings.Index(pattern, "**"); i >= 0 {
+		pattern = pattern[:i]
+	}
+	return filepath.Clean(pattern)
+}
+
+// matchesFilters reports whether name (a covered source path, e.g.
+// p.FileName) should be kept given the --include/--exclude globs: name
+// must match at least one include pattern (if any are given), and must not
+// match any exclude pattern.
+func matchesFilters(name string, includes, excludes []string) bool {
+	if len(includes) > 0 {
+		included := false
+		for _, pat := range includes {
+			if matchPattern(pat, name) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, pat := range excludes {
+		if matchPattern(pat, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchPattern(pattern, name string) bool {
+	if strings.Contains(pattern, "**") {
+		re, err := regexp.Compile(doublestarToRegexp(pattern))
+		if err != nil {
+			log.Print(err)
+			return false
+		}
+		return re.MatchString(name)
+	}
+
+	ok, err := filepath.Match(pattern, name)
+	if err != nil {
+		log.Print(err)
+		return false
+	}
+	return ok
+}
+
+func doublestarToRegexp(pattern string) string {
+	b := &strings.Builder{}
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestExpandDoublestarGlobNested(t *testing.T) {
+	dir := t.TempDir()
+
+	files := []string{
+		filepath.Join("ci-artifacts", "job1", "a.out"),
+		filepath.Join("ci-artifacts", "job1", "sub", "b.out"),
+		filepath.Join("ci-artifacts", "job2", "c.out"),
+	}
+	for _, f := range files {
+		full := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(full, []byte("mode: set\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pattern := filepath.Join(dir, "ci-artifacts", "**", "*.out")
+	got := expandDoublestarGlob(pattern)
+	sort.Strings(got)
+
+	var want []string
+	for _, f := range files {
+		want = append(want, filepath.Join(dir, f))
+	}
+	sort.Strings(want)
+
+	if len(got) != len(want) {
+		t.Fatalf("expandDoublestarGlob(%q) = %v, want %v", pattern, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expandDoublestarGlob(%q)[%d] = %q, want %q", pattern, i, got[i], want[i])
+		}
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"vendor/**", "vendor/pkg/file.go", true},
+		{"vendor/**", "main.go", false},
+		{"**/*_gen.go", "pkg/sub/thing_gen.go", true},
+		{"**/*_gen.go", "pkg/sub/thing.go", false},
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+	}
+
+	for _, c := range cases {
+		if got := matchPattern(c.pattern, c.name); got != c.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
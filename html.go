@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/cover"
+)
+
+type (
+	htmlContext struct {
+		Percent float64
+		Files   []*htmlFile
+	}
+
+	htmlFile struct {
+		ID   string
+		Name string
+		Body string
+	}
+
+	boundary struct {
+		offset int
+		start  bool
+		count  int
+	}
+)
+
+// writeHTMLReport renders ctx.Results as an annotated-source HTML document,
+// similar to `go tool cover -html`, to outpath.
+func writeHTMLReport(ctx context, projRoot, outpath string) {
+	hctx := htmlContext{Percent: coveragePercent(ctx)}
+
+	for i, f := range sortedFilenames(ctx) {
+		r := ctx.Results[f]
+		content, err := ioutil.ReadFile(filepath.Join(projRoot, f))
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		hctx.Files = append(hctx.Files, &htmlFile{
+			ID:   fmt.Sprintf("file%d", i),
+			Name: f,
+			Body: renderFileHTML(content, r.Blocks),
+		})
+	}
+
+	tmpl := getTemplate("html.tmpl")
+
+	out := &bytes.Buffer{}
+	if err := tmpl.Execute(out, hctx); err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(outpath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(out.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func coveragePercent(ctx context) float64 {
+	var hits, total int
+	for _, r := range ctx.Results {
+		hits += r.HitCount()
+		total += r.HitCount() + r.MissCount()
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total) * 100
+}
+
+// renderFileHTML wraps runs of content covered by a profile block in a
+// <span> classed by coverage bucket (cov0..cov10), computed from the
+// block's Count relative to the highest Count seen in this file. Text
+// outside any block (whitespace, braces, etc.) is emitted unadorned, as
+// go tool cover's own html output does.
+func renderFileHTML(content []byte, blocks []cover.ProfileBlock) string {
+	lineOffsets := lineStartOffsets(content)
+
+	max := 0
+	for _, b := range blocks {
+		if b.Count > max {
+			max = b.Count
+		}
+	}
+
+	var bounds []boundary
+	for _, b := range blocks {
+		bounds = append(bounds,
+			boundary{offset: offsetFor(lineOffsets, b.StartLine, b.StartCol), start: true, count: b.Count},
+			boundary{offset: offsetFor(lineOffsets, b.EndLine, b.EndCol), start: false, count: b.Count},
+		)
+	}
+	sort.Slice(bounds, func(i, j int) bool {
+		if bounds[i].offset == bounds[j].offset {
+			return !bounds[i].start && bounds[j].start
+		}
+		return bounds[i].offset < bounds[j].offset
+	})
+
+	buf := &bytes.Buffer{}
+	last := 0
+	for _, b := range bounds {
+		offset := b.offset
+		if offset > len(content) {
+			offset = len(content)
+		}
+		if offset > last {
+			buf.WriteString(html.EscapeString(string(content[last:offset])))
+			last = offset
+		}
+		if b.start {
+			buf.WriteString(`<span class="` + covClass(b.count, max) + `">`)
+		} else {
+			buf.WriteString("</span>")
+		}
+	}
+	if last < len(content) {
+		buf.WriteString(html.EscapeString(string(content[last:])))
+	}
+	return buf.String()
+}
+
+func covClass(count, max int) string {
+	if count == 0 || max <= 0 {
+		return "cov0"
+	}
+	bucket := count * 10 / max
+	if bucket < 1 {
+		bucket = 1
+	}
+	if bucket > 10 {
+		bucket = 10
+	}
+	return "cov" + strconv.Itoa(bucket)
+}
+
+func lineStartOffsets(content []byte) []int {
+	offsets := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+func offsetFor(lineOffsets []int, line, col int) int {
+	if line-1 >= len(lineOffsets) {
+		line = len(lineOffsets)
+	}
+	return lineOffsets[line-1] + col - 1
+}
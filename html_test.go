@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/cover"
+)
+
+// A stale profile (recorded against an older version of the source) can
+// carry an EndCol past the end of the current line; offsetFor then
+// computes an offset beyond len(content), which the boundary loop used to
+// drop silently via "continue", leaving the opening <span> unclosed.
+func TestRenderFileHTMLClampsOutOfBoundsEndCol(t *testing.T) {
+	body := renderFileHTML([]byte("ab\n"), []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 100, Count: 1},
+	})
+
+	if got, want := strings.Count(body, "<span"), strings.Count(body, "</span>"); got != want {
+		t.Errorf("unbalanced spans: %d opened, %d closed (body=%q)", got, want, body)
+	}
+	if !strings.HasSuffix(body, "</span>") {
+		t.Errorf("body = %q, want it to end with a closed </span>", body)
+	}
+}
+
+func TestRenderFileHTMLInBoundsBlock(t *testing.T) {
+	body := renderFileHTML([]byte("ab\n"), []cover.ProfileBlock{
+		{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 3, Count: 1},
+	})
+
+	want := `<span class="cov10">ab</span>` + "\n"
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
@@ -22,7 +22,7 @@ import (
 
 const (
 	docstring = `Parse go coverage profiles into vim-legend files
-Usage: legendary [options] <outpath> <sourcefiles>...
+Usage: legendary [options] [--include=<glob>]... [--exclude=<glob>]... <outpath> <sourcefiles>...
        legendary [options] --noout <sourcefiles>...
 
 Options:
@@ -31,6 +31,13 @@ Options:
 	--hitlist             Don't produce vim-legend output - instead repoort on worst covered files
 	--limit=<n>           Limit the number of files in the hitlist to <n>
 	--noout               Don't record the coverage anywhere (use with hitlist)
+	--html=<file>         Emit an annotated HTML coverage report (like 'go tool cover -html') to <file>
+	--format=<fmt>        Output format for <outpath>: legend, lcov, or cobertura (Default: legend)
+	--baseline=<file>     Diff this run against the JSON snapshot at <file>, then update it (for CI gating)
+	--fail-under=<pct>    Exit non-zero if overall coverage is below <pct> (checked only with --baseline)
+	--fail-on-regression  Exit non-zero if any file's coverage percentage dropped versus --baseline
+	--include=<glob>      Only keep covered files matching <glob> (repeatable)
+	--exclude=<glob>      Drop covered files matching <glob>, e.g. vendor/** (repeatable)
 `
 )
 
@@ -40,18 +47,26 @@ type (
 		hitlist           bool
 		limit             uint
 		outpath           string
+		html              string
+		format            string
+		baseline          string
+		failUnder         float64
+		failOnRegression  bool
+		include, exclude  []string
 		sourcefiles       []string
 	}
 
 	context struct {
-		Now     int64
-		Results map[string]*result
+		Now      int64
+		Results  map[string]*result
+		Profiles []*cover.Profile
 	}
 
 	result struct {
 		filename string
 		lines    int
-		counts   map[int]int
+		Counts   map[int]int
+		Blocks   []cover.ProfileBlock
 		Hits     []int
 		Misses   []int
 		Ignored  []int
@@ -99,13 +114,26 @@ func main() {
 	log.SetFlags(log.Flags() | log.Lshortfile)
 	opts := parseOpts()
 
-	ctx := collectCoverageContext(opts.coverage, opts.project, opts.sourcefiles)
+	ctx := collectCoverageContext(opts.coverage, opts.project, opts.sourcefiles, opts.include, opts.exclude)
 
 	if opts.hitlist {
 		printHitlist(ctx, int(opts.limit))
 	}
 
+	if opts.html != "" {
+		writeHTMLReport(ctx, opts.project, opts.html)
+	}
+
+	gatePassed := true
+	if opts.baseline != "" {
+		gatePassed = gateCoverage(ctx, opts)
+	}
+
 	writeOut(ctx, opts)
+
+	if !gatePassed {
+		os.Exit(1)
+	}
 }
 
 func printHitlist(ctx context, limit int) {
@@ -139,33 +167,91 @@ func printHitlist(ctx context, limit int) {
 	tabs.Flush()
 }
 
-func ingestCoverageFile(ctx *context, coverageRoot, projRoot, fp string) {
-	ps, err := cover.ParseProfiles(fp)
-	if err != nil {
-		log.Print(err)
-		return
-	}
+// ingestCoverageFile expands the sourcefiles entry fp (a literal path, a
+// glob, or a "**" pattern) into the profile files it names, parses each,
+// and drops any covered file not selected by includes/excludes - before
+// the caller ever builds a ctx.Results entry from it.
+func ingestCoverageFile(fp string, includes, excludes []string) []*cover.Profile {
+	var profiles []*cover.Profile
 
-	for _, p := range ps {
-		an := filepath.Join(coverageRoot, p.FileName)
-		rn, err := filepath.Rel(projRoot, an)
+	for _, path := range expandSourceFiles(fp) {
+		ps, err := cover.ParseProfiles(path)
 		if err != nil {
 			log.Print(err)
-			return
+			continue
 		}
 
-		r, ok := ctx.Results[rn]
+		for _, p := range ps {
+			if matchesFilters(p.FileName, includes, excludes) {
+				profiles = append(profiles, p)
+			}
+		}
+	}
+
+	return profiles
+}
+
+// mergeProfiles unions profiles covering the same FileName, summing Count
+// only across blocks that share the same (StartLine, StartCol, EndLine,
+// EndCol) - the same scheme as golang.org/x/tools/cover's own
+// addCounts/MergeProfiles - rather than the line-by-line accumulation this
+// replaced, which double-counted lines shared by overlapping blocks.
+func mergeProfiles(profiles []*cover.Profile) []*cover.Profile {
+	var order []string
+	byFile := make(map[string]*cover.Profile)
+
+	for _, p := range profiles {
+		m, ok := byFile[p.FileName]
 		if !ok {
-			r = &result{filename: rn, counts: make(map[int]int)}
-			ctx.Results[rn] = r
+			m = &cover.Profile{FileName: p.FileName, Mode: p.Mode}
+			byFile[p.FileName] = m
+			order = append(order, p.FileName)
+		}
+		for _, b := range p.Blocks {
+			mergeBlock(m, b)
+		}
+	}
+
+	merged := make([]*cover.Profile, 0, len(order))
+	for _, fn := range order {
+		merged = append(merged, byFile[fn])
+	}
+	return merged
+}
+
+func mergeBlock(p *cover.Profile, b cover.ProfileBlock) {
+	for i := range p.Blocks {
+		eb := &p.Blocks[i]
+		if eb.StartLine == b.StartLine && eb.StartCol == b.StartCol &&
+			eb.EndLine == b.EndLine && eb.EndCol == b.EndCol {
+			eb.Count += b.Count
+			return
 		}
-		for _, pb := range p.Blocks {
-			for ln := pb.StartLine; ln <= pb.EndLine; ln++ {
-				r.counts[ln] += pb.Count
+	}
+	p.Blocks = append(p.Blocks, b)
+}
+
+func buildResult(ctx *context, coverageRoot, projRoot string, p *cover.Profile) {
+	an := filepath.Join(coverageRoot, p.FileName)
+	rn, err := filepath.Rel(projRoot, an)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	r, ok := ctx.Results[rn]
+	if !ok {
+		r = &result{filename: rn, Counts: make(map[int]int)}
+		ctx.Results[rn] = r
+	}
+	for _, pb := range p.Blocks {
+		for ln := pb.StartLine; ln <= pb.EndLine; ln++ {
+			if c, ok := r.Counts[ln]; !ok || pb.Count > c {
+				r.Counts[ln] = pb.Count
 			}
 		}
 	}
-	return
+	r.Blocks = append(r.Blocks, p.Blocks...)
 }
 
 func buildFileCoverage(ctx *context, projRoot, f string, r *result) {
@@ -184,8 +270,8 @@ func buildFileCoverage(ctx *context, projRoot, f string, r *result) {
 	}
 	r.lines = lines
 
-	for ln := 0; ln < r.lines; ln++ {
-		c, ok := r.counts[ln]
+	for ln := 1; ln <= r.lines; ln++ {
+		c, ok := r.Counts[ln]
 		switch {
 		default:
 			r.Misses = append(r.Misses, ln)
@@ -277,12 +363,18 @@ func newEscaper(r io.Reader) *escaper {
 	return &escaper{r, make([]byte, 0), false}
 }
 
-func collectCoverageContext(coverageRoot string, projRoot string, sourceFiles []string) context {
+func collectCoverageContext(coverageRoot string, projRoot string, sourceFiles []string, includes, excludes []string) context {
 	var ctx context
 	ctx.Results = make(map[string]*result)
 
+	var parsed []*cover.Profile
 	for _, fp := range sourceFiles {
-		ingestCoverageFile(&ctx, coverageRoot, projRoot, fp)
+		parsed = append(parsed, ingestCoverageFile(fp, includes, excludes)...)
+	}
+	ctx.Profiles = mergeProfiles(parsed)
+
+	for _, p := range ctx.Profiles {
+		buildResult(&ctx, coverageRoot, projRoot, p)
 	}
 
 	for f, r := range ctx.Results {
@@ -303,6 +395,7 @@ func parseOpts() options {
 	opts := options{
 		coverage: filepath.Join(os.Getenv("GOPATH"), "src"),
 		project:  pwd,
+		format:   "legend",
 	}
 	log.Printf("%# v", opts)
 	err = coerce.Struct(&opts, parsed, "-%s", "--%s", "<%s>")
@@ -317,7 +410,18 @@ func parseOpts() options {
 }
 
 func writeOut(ctx context, opts options) {
-	tmpl := getTemplate("coverage.tmpl")
+	var tmplName string
+	var data interface{}
+
+	switch opts.format {
+	case "lcov":
+		tmplName, data = "lcov.tmpl", buildLcovContext(ctx)
+	case "cobertura":
+		tmplName, data = "cobertura.tmpl", buildCoberturaContext(ctx)
+	default:
+		tmplName, data = "coverage.tmpl", ctx
+	}
+	tmpl := getTemplate(tmplName)
 
 	out := &bytes.Buffer{}
 	file, err := os.Create(opts.outpath)
@@ -325,7 +429,7 @@ func writeOut(ctx context, opts options) {
 		log.Fatal(err)
 	}
 
-	tmpl.Execute(out, ctx)
+	tmpl.Execute(out, data)
 
 	_, err = file.Write(out.Bytes())
 	if err != nil {
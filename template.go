@@ -6,4 +6,10 @@ package main
 
 const (
 	coverageTmpl = "let s:generatedTime = {{ .Now }}\nlet s:coverageResults = {\n{{ range $file, $coverage := .Results }}\\'{{ $file }}': {\n\\  'hits': [\n{{- range .Hits -}}\n{{.}},\n{{- end -}}\n],\n\\  'misses': [\n{{- range .Misses -}}\n{{.}},\n{{- end -}}\n],\n\\  'ignored': [\n{{- range .Ignored -}}\n{{.}},\n{{- end -}}\n],\n\\  },\n{{ end -}}\n\\}\ncall AddSimplecovResults(expand(\"<sfile>:p\"), s:coverageResults)\n"
+
+	htmlTmpl = "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<style>\nbody { background: white; color: black; font-family: Menlo, monospace; }\n#nav { margin-bottom: 1em; }\n.cov0 { color: rgb(192, 0, 0) }\n.cov1 { color: rgb(192, 64, 0) }\n.cov2 { color: rgb(192, 96, 0) }\n.cov3 { color: rgb(192, 128, 0) }\n.cov4 { color: rgb(170, 150, 0) }\n.cov5 { color: rgb(136, 160, 0) }\n.cov6 { color: rgb(96, 160, 0) }\n.cov7 { color: rgb(64, 160, 32) }\n.cov8 { color: rgb(32, 160, 64) }\n.cov9 { color: rgb(16, 160, 96) }\n.cov10 { color: rgb(0, 160, 128) }\n</style>\n<script>\nfunction legendaryShowFile(id) {\n\tvar files = document.getElementsByClassName(\"file\");\n\tfor (var i = 0; i < files.length; i++) {\n\t\tfiles[i].style.display = \"none\";\n\t}\n\tdocument.getElementById(id).style.display = \"block\";\n}\n</script>\n</head>\n<body>\n<div id=\"nav\">\n\t<span>Coverage: {{ printf \"%.1f\" .Percent }}%</span>\n\t<select onchange=\"legendaryShowFile(this.value)\">\n\t{{ range .Files }}<option value=\"{{ .ID }}\">{{ .Name }}</option>\n\t{{ end }}</select>\n</div>\n{{ range $i, $f := .Files }}<pre class=\"file\" id=\"{{ $f.ID }}\" style=\"display: {{ if eq $i 0 }}block{{ else }}none{{ end }};\">{{ $f.Body }}</pre>\n{{ end }}</body>\n</html>\n"
+
+	lcovTmpl = "{{ range .Files }}SF:{{ .Name }}\n{{ range .Lines }}DA:{{ .Line }},{{ .Count }}\n{{ end }}LF:{{ .LinesFound }}\nLH:{{ .LinesHit }}\nend_of_record\n{{ end }}\n"
+
+	coberturaTmpl = "<?xml version=\"1.0\"?>\n<coverage line-rate=\"{{ printf \"%.4f\" .LineRate }}\" branch-rate=\"0\" version=\"legendary\">\n<packages>\n<package name=\"main\" line-rate=\"{{ printf \"%.4f\" .LineRate }}\" branch-rate=\"0\">\n<classes>\n{{ range .Files }}<class name=\"{{ .Name }}\" filename=\"{{ .Name }}\" line-rate=\"{{ printf \"%.4f\" .LineRate }}\" branch-rate=\"0\">\n<lines>\n{{ range .Lines }}<line number=\"{{ .Line }}\" hits=\"{{ .Count }}\"/>\n{{ end }}</lines>\n</class>\n{{ end }}</classes>\n</package>\n</packages>\n</coverage>\n"
 )